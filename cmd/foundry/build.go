@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// buildImages builds p.Cfg.Base.Architecture's images concurrently, then
+// assembles them into a single multi-arch manifest list. A failure in one
+// architecture's build does not cancel the others; all results are
+// collected before the first error (if any) is returned. On success it
+// returns the pushed manifest list's digest, which the signing pipeline
+// (see sign.go) signs and attests against.
+func buildImages(ctx context.Context, p *Project) (string, error) {
+	archs := p.Cfg.Base.Architecture
+	errs := make([]error, len(archs))
+
+	// Read stdin once up front: os.Stdin is a single shared pipe, so if each
+	// arch's goroutine read it independently only the first would see any
+	// data and the rest would build from an empty Dockerfile.
+	var stdin []byte
+	if buildFile == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read Dockerfile from stdin: %w", err)
+		}
+		stdin = data
+	}
+
+	// Resolve the base template once too: resolveTemplate does a
+	// read-modify-write of foundry.lock (and, for oci:// / git+ refs, a
+	// network fetch), which would race across arch goroutines and fetch the
+	// same template N times over if left in the default-branch per-arch path.
+	var templatePath string
+	if buildFile == "" {
+		resolved, err := p.resolveTemplate(ctx, false)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve base template: %w", err)
+		}
+		templatePath = resolved.Path
+	}
+
+	var wg sync.WaitGroup
+	for i, arch := range archs {
+		i, arch := i, arch
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fmt.Printf("Building for architecture: %s\n", arch)
+			if err := buildImage(ctx, p, arch, stdin, templatePath); err != nil {
+				errs[i] = fmt.Errorf("arch %s: %w", arch, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var firstErr error
+	for _, err := range errs {
+		if err != nil {
+			p.Logger.Error("architecture build failed", "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if firstErr != nil {
+		return "", firstErr
+	}
+
+	return assembleManifest(ctx, p, archs)
+}
+
+// buildImage invokes `docker buildx build` for a single architecture via
+// BuildKit, wiring up registry cache mounts (p.Cfg.Optimization.CacheLayers)
+// and provenance/SBOM attestation (p.Cfg.Output.Attestation/SBOM). The
+// result is pushed to imageName-arch; assembleManifest later combines the
+// per-arch tags into one manifest list.
+func buildImage(ctx context.Context, p *Project, arch string, stdin []byte, templatePath string) error {
+	imageName := p.imageName()
+
+	dockerfilePath, cleanup, err := resolveDockerfile(ctx, p, buildFile, arch, stdin, templatePath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve Dockerfile: %w", err)
+	}
+	defer cleanup()
+
+	buildArgs := []string{
+		"buildx", "build",
+		"--platform", "linux/" + arch,
+		"--file", dockerfilePath,
+		"--tag", imageName + "-" + arch,
+	}
+
+	if p.Cfg.Optimization.CacheLayers {
+		cacheRef := imageName + "-cache-" + arch
+		buildArgs = append(buildArgs,
+			"--cache-to", "type=registry,ref="+cacheRef+",mode=max",
+			"--cache-from", "type=registry,ref="+cacheRef,
+		)
+	}
+
+	if p.Cfg.Output.Attestation.Provenance {
+		buildArgs = append(buildArgs, "--provenance=true")
+	}
+	if p.Cfg.Output.SBOM.Enabled {
+		buildArgs = append(buildArgs, "--sbom=true")
+	}
+
+	for toolName, toolConfig := range p.Cfg.Tools.Languages {
+		if toolConfig.Install {
+			buildArgs = append(buildArgs, "--build-arg", fmt.Sprintf("%s_VERSION=%s",
+				strings.ToUpper(toolName), toolConfig.Version))
+		}
+	}
+
+	buildArgs = append(buildArgs, "--push", ".")
+
+	return runDocker(ctx, p.Logger, buildArgs)
+}
+
+// assembleManifest combines the per-arch tags buildImage pushed into a
+// single manifest list at imageName, so a single "ghcr.io/org/img:tag"
+// resolves to every built architecture. It returns the resulting manifest
+// list's digest, read back the same way resolveDigest (see sign.go) reads
+// a tag's digest: `imagetools create` has no --metadata-file flag (that
+// belongs to `buildx build`/`bake`), so the digest has to come from a
+// separate `imagetools inspect` call after the create.
+func assembleManifest(ctx context.Context, p *Project, archs []string) (string, error) {
+	imageName := p.imageName()
+
+	args := []string{"buildx", "imagetools", "create", "--tag", imageName}
+	for _, arch := range archs {
+		args = append(args, imageName+"-"+arch)
+	}
+
+	if err := runDocker(ctx, p.Logger, args); err != nil {
+		return "", err
+	}
+
+	return resolveDigest(ctx, imageName)
+}
+
+// runDocker runs `docker <args...>`, streaming stdout/stderr into logger
+// line-by-line as it runs rather than buffering it all until exit.
+func runDocker(ctx context.Context, logger *slog.Logger, args []string) error {
+	fmt.Printf("  Running: docker %s\n", strings.Join(args, " "))
+
+	cmd := exec.CommandContext(ctx, "docker", args...)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start docker: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go streamTo(&wg, logger, "stdout", stdout)
+	go streamTo(&wg, logger, "stderr", stderr)
+	wg.Wait()
+
+	return cmd.Wait()
+}
+
+// runCommand runs a user-configured shell hook (custom.pre_build /
+// custom.post_build), streaming its output to stdout/stderr directly.
+func runCommand(ctx context.Context, command string) error {
+	fmt.Printf("Executing: %s\n", command)
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func streamTo(wg *sync.WaitGroup, logger *slog.Logger, stream string, r io.Reader) {
+	defer wg.Done()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		logger.Info(scanner.Text(), "stream", stream)
+	}
+}
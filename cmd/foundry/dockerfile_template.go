@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// resolveDockerfile figures out the concrete Dockerfile to hand to
+// `docker buildx build -f`. It supports three forms of the --file flag:
+//
+//   - "-"                   use the Dockerfile already read from stdin
+//   - "*.Dockerfile.in"     run it through preprocessTemplate for arch
+//   - anything else / ""    used as-is (falls back to the base template)
+//
+// When file is empty, the configured base template is used instead --
+// resolved locally or, for oci:// and git+ refs, fetched (or served from
+// cache) through Project.resolveTemplate.
+//
+// stdin is the Dockerfile content read from os.Stdin once, before the
+// per-architecture fan-out in buildImages; resolveDockerfile itself never
+// reads os.Stdin; since buildImages runs one goroutine per architecture and
+// only the first read of a shared stdin pipe would see any data, reading
+// per-call would leave every architecture but one with an empty file.
+//
+// templatePath is the base template already resolved by buildImages (via
+// Project.resolveTemplate) before the fan-out, used when file is empty.
+// resolveTemplate does a read-modify-write of foundry.lock and, for remote
+// refs, a network fetch; resolving it once per arch goroutine would race on
+// the lock file and fetch the same template redundantly.
+//
+// The returned path always points at a plain, ready-to-build Dockerfile;
+// callers are responsible for cleaning up temp files it may have created.
+func resolveDockerfile(ctx context.Context, p *Project, file, arch string, stdin []byte, templatePath string) (path string, cleanup func(), err error) {
+	noop := func() {}
+
+	switch {
+	case file == "-":
+		tmp, err := writeTempDockerfile(stdin, arch)
+		if err != nil {
+			return "", noop, err
+		}
+		return tmp, func() { os.Remove(tmp) }, nil
+
+	case strings.HasSuffix(file, ".Dockerfile.in"):
+		rendered, err := preprocessTemplate(p, file, arch)
+		if err != nil {
+			return "", noop, fmt.Errorf("failed to preprocess %s: %w", file, err)
+		}
+		tmp, err := writeTempDockerfile([]byte(rendered), arch)
+		if err != nil {
+			return "", noop, err
+		}
+		return tmp, func() { os.Remove(tmp) }, nil
+
+	case file != "":
+		return file, noop, nil
+
+	default:
+		return templatePath, noop, nil
+	}
+}
+
+func writeTempDockerfile(data []byte, arch string) (string, error) {
+	// os.CreateTemp rejects patterns containing a path separator, and arch
+	// values like "arm/v7" contain one, so sanitize before interpolating.
+	safeArch := strings.ReplaceAll(arch, "/", "-")
+
+	f, err := os.CreateTemp("", fmt.Sprintf("foundry-%s-*.Dockerfile", safeArch))
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp Dockerfile: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return "", fmt.Errorf("failed to write temp Dockerfile: %w", err)
+	}
+
+	return f.Name(), nil
+}
+
+// preprocessTemplate runs a cpp-style pass over a .Dockerfile.in file and
+// returns the rendered Dockerfile contents for the given architecture. It
+// supports:
+//
+//   - ${VAR} substitution sourced from p.Cfg.Tools.Languages versions and
+//     p.Cfg.Tools.Packages (joined with a space for bulk installs)
+//   - #include <path> of fragments resolved relative to p.Root/templates/fragments/
+//   - #if ARCH == "arm64" / #endif conditional blocks, evaluated against arch
+//
+// It is intentionally small: this is not a general-purpose preprocessor,
+// just enough to let base templates share fragments across architectures.
+func preprocessTemplate(p *Project, path, arch string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	vars := templateVars(p)
+	return renderLines(p, bufio.NewScanner(f), vars, arch)
+}
+
+func renderLines(p *Project, scanner *bufio.Scanner, vars map[string]string, arch string) (string, error) {
+	var out strings.Builder
+	skipping := false
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(line), "#if "):
+			skipping = !evalCondition(strings.TrimSpace(line), arch)
+			continue
+		case strings.TrimSpace(line) == "#endif":
+			skipping = false
+			continue
+		case skipping:
+			continue
+		case strings.HasPrefix(strings.TrimSpace(line), "#include "):
+			included, err := includeFragment(p, strings.TrimSpace(line), vars, arch)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(included)
+			continue
+		}
+
+		out.WriteString(substituteVars(line, vars))
+		out.WriteString("\n")
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+func includeFragment(p *Project, directive string, vars map[string]string, arch string) (string, error) {
+	name := strings.Trim(strings.TrimPrefix(directive, "#include"), ` "`)
+	fragmentPath := filepath.Join(p.Root, "templates", "fragments", name)
+
+	f, err := os.Open(fragmentPath)
+	if err != nil {
+		return "", fmt.Errorf("fragment %q not found under %s: %w", name, filepath.Dir(fragmentPath), err)
+	}
+	defer f.Close()
+
+	return renderLines(p, bufio.NewScanner(f), vars, arch)
+}
+
+// evalCondition supports the single conditional shape the config DSL needs:
+// `#if ARCH == "arm64"`.
+func evalCondition(directive string, arch string) bool {
+	directive = strings.TrimPrefix(directive, "#if")
+	directive = strings.TrimSpace(directive)
+
+	parts := strings.SplitN(directive, "==", 2)
+	if len(parts) != 2 {
+		return true
+	}
+
+	lhs := strings.TrimSpace(parts[0])
+	rhs := strings.Trim(strings.TrimSpace(parts[1]), `"`)
+
+	if lhs != "ARCH" {
+		return true
+	}
+
+	return arch == rhs
+}
+
+func substituteVars(line string, vars map[string]string) string {
+	for name, value := range vars {
+		line = strings.ReplaceAll(line, "${"+name+"}", value)
+	}
+	return line
+}
+
+func templateVars(p *Project) map[string]string {
+	vars := make(map[string]string)
+
+	for name, tool := range p.Cfg.Tools.Languages {
+		vars[strings.ToUpper(name)+"_VERSION"] = tool.Version
+	}
+
+	vars["PACKAGES"] = strings.Join(p.Cfg.Tools.Packages, " ")
+
+	return vars
+}
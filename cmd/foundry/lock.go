@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lockFileName is written next to image-foundry.yaml, never under .foundry/,
+// so it's natural to check into version control alongside the config it pins.
+const lockFileName = "foundry.lock"
+
+// Lock records the content digest each remote (oci:// or git+) template ref
+// last resolved to, so repeat builds reuse that digest instead of
+// re-resolving the ref. Run "foundry template update" to refresh it.
+type Lock struct {
+	Templates map[string]string `yaml:"templates"`
+}
+
+func readLock(root string) (Lock, error) {
+	data, err := os.ReadFile(filepath.Join(root, lockFileName))
+	if os.IsNotExist(err) {
+		return Lock{Templates: map[string]string{}}, nil
+	}
+	if err != nil {
+		return Lock{}, fmt.Errorf("failed to read %s: %w", lockFileName, err)
+	}
+
+	var lock Lock
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return Lock{}, fmt.Errorf("failed to parse %s: %w", lockFileName, err)
+	}
+	if lock.Templates == nil {
+		lock.Templates = map[string]string{}
+	}
+
+	return lock, nil
+}
+
+func writeLock(root string, lock Lock) error {
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", lockFileName, err)
+	}
+
+	return os.WriteFile(filepath.Join(root, lockFileName), data, 0644)
+}
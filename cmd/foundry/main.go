@@ -1,13 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
+	"github.com/simonbbbb/imagefoundry/internal/scanners"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 // Config represents the main configuration structure
@@ -166,11 +166,6 @@ type AttestationConfig struct {
 	SBOMAttestation bool `yaml:"sbom_attestation" json:"sbom_attestation"`
 }
 
-var (
-	cfgFile string
-	config  Config
-)
-
 var rootCmd = &cobra.Command{
 	Use:   "foundry",
 	Short: "ImageFoundry - Build custom container images with E2E CI/CD",
@@ -184,8 +179,7 @@ Complete documentation is available at https://github.com/yourorg/imagefoundry`,
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./image-foundry.yaml)")
+	rootCmd.PersistentFlags().String("config", "", "config file (default is ./image-foundry.yaml)")
 
 	rootCmd.AddCommand(buildCmd)
 	rootCmd.AddCommand(validateCmd)
@@ -195,189 +189,219 @@ func init() {
 	rootCmd.AddCommand(initCmd)
 }
 
-func initConfig() {
-	if cfgFile != "" {
-		// Use config file from the flag
-	} else {
-		// Search for config in current directory
-		cfgFile = "image-foundry.yaml"
-	}
-
-	if _, err := os.Stat(cfgFile); err == nil {
-		data, err := os.ReadFile(cfgFile)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
-			return
-		}
-
-		if err := yaml.Unmarshal(data, &config); err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing config: %v\n", err)
-			return
-		}
-	}
+// configPath reads the --config persistent flag off cmd (or an ancestor,
+// since it's only registered on rootCmd).
+func configPath(cmd *cobra.Command) string {
+	path, _ := cmd.Flags().GetString("config")
+	return path
 }
 
+var buildFile string
+
 var buildCmd = &cobra.Command{
 	Use:   "build",
 	Short: "Build container images from configuration",
-	Long:  `Builds container images based on the provided configuration file.`,
+	Long: `Builds container images based on the provided configuration file.
+
+By default the Dockerfile is resolved from the configured base template,
+but --file lets you override it: pass "-" to pipe a Dockerfile on stdin,
+or point at a "*.Dockerfile.in" template to run it through foundry's
+fragment/variable preprocessor before building (mirrors "foundry build -f -").`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("üî® Building container images...")
+		fmt.Println("\U0001f528 Building container images...")
 
-		// Load configuration
-		if err := loadConfig(); err != nil {
+		ctx := cmd.Context()
+
+		project, err := LoadProject(ctx, configPath(cmd))
+		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
 		// Run pre-build hook
-		if config.Custom.PreBuild != "" {
+		if project.Cfg.Custom.PreBuild != "" {
 			fmt.Println("Running pre-build hook...")
-			if err := runCommand(config.Custom.PreBuild); err != nil {
+			if err := runCommand(ctx, project.Cfg.Custom.PreBuild); err != nil {
 				return fmt.Errorf("pre-build hook failed: %w", err)
 			}
 		}
 
-		// Build for each architecture
-		for _, arch := range config.Base.Architecture {
-			fmt.Printf("Building for architecture: %s\n", arch)
-			if err := buildImage(arch); err != nil {
-				return fmt.Errorf("failed to build for %s: %w", arch, err)
-			}
+		// Build all configured architectures in parallel and assemble a
+		// single multi-arch manifest list once every arch has pushed.
+		digest, err := buildImages(ctx, project)
+		if err != nil {
+			return fmt.Errorf("build failed: %w", err)
+		}
+
+		if err := signAndAttest(ctx, project, digest); err != nil {
+			return fmt.Errorf("signing failed: %w", err)
 		}
 
 		// Run post-build hook
-		if config.Custom.PostBuild != "" {
+		if project.Cfg.Custom.PostBuild != "" {
 			fmt.Println("Running post-build hook...")
-			if err := runCommand(config.Custom.PostBuild); err != nil {
+			if err := runCommand(ctx, project.Cfg.Custom.PostBuild); err != nil {
 				return fmt.Errorf("post-build hook failed: %w", err)
 			}
 		}
 
-		fmt.Println("‚úÖ Build completed successfully!")
+		fmt.Println("✅ Build completed successfully!")
 		return nil
 	},
 }
 
+func init() {
+	buildCmd.Flags().StringVarP(&buildFile, "file", "f", "", `Dockerfile to build from: "-" for stdin, a path, or a "*.Dockerfile.in" template (defaults to the base template)`)
+}
+
 var validateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "Validate configuration file",
-	Long:  `Validates the image-foundry.yaml configuration file.`,
+	Long: `Validates the image-foundry.yaml configuration file against the
+generated JSON Schema (see "foundry schema"), catching unknown keys, typos,
+and bad enum values with JSON-pointer paths rather than just the handful of
+required fields checked before.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("üîç Validating configuration...")
+		fmt.Println("\U0001f50d Validating configuration...")
 
-		if err := loadConfig(); err != nil {
-			return fmt.Errorf("validation failed: %w", err)
+		path := configPath(cmd)
+		if path == "" {
+			path = "image-foundry.yaml"
 		}
 
-		// Validate required fields
-		if config.Name == "" {
-			return fmt.Errorf("project name is required")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("validation failed: %w", err)
 		}
 
-		if config.Base.Template == "" {
-			return fmt.Errorf("base template is required")
+		if err := validateConfig(data, filepath.Dir(path)); err != nil {
+			return fmt.Errorf("validation failed: %w", err)
 		}
 
-		if len(config.Base.Architecture) == 0 {
-			return fmt.Errorf("at least one architecture is required")
+		project, err := LoadProject(cmd.Context(), path)
+		if err != nil {
+			return fmt.Errorf("validation failed: %w", err)
 		}
 
-		// Check if template exists
-		templatePath := filepath.Join("templates", "base", config.Base.Template+".Dockerfile")
-		if _, err := os.Stat(templatePath); os.IsNotExist(err) {
-			return fmt.Errorf("template '%s' not found at %s", config.Base.Template, templatePath)
+		// Resolve the base template, whether that's a local file, an OCI
+		// artifact, or a git ref.
+		if _, err := project.resolveTemplate(cmd.Context(), false); err != nil {
+			return fmt.Errorf("template '%s' could not be resolved: %w", project.Cfg.Base.Template, err)
 		}
 
-		fmt.Println("‚úÖ Configuration is valid!")
+		fmt.Println("✅ Configuration is valid!")
 		return nil
 	},
 }
 
+var testParallel int
+
 var testCmd = &cobra.Command{
 	Use:   "test",
 	Short: "Run tests on built images",
-	Long:  `Runs structure tests, integration tests, and performance tests on built images.`,
+	Long: `Runs structure tests, integration tests, and performance tests on
+built images as pluggable scanners.Scanner implementations, fanned out
+with --parallel workers and a per-scanner timeout sourced from
+testing.integration_tests.timeout. Results are aggregated into a SARIF
+2.1.0 report under ./.foundry/reports/test.sarif.json.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("üß™ Running tests...")
+		fmt.Println("\U0001f9ea Running tests...")
 
-		if err := loadConfig(); err != nil {
+		ctx := cmd.Context()
+		project, err := LoadProject(ctx, configPath(cmd))
+		if err != nil {
 			return err
 		}
 
-		// Structure tests
-		if config.Testing.StructureTests.Enabled {
-			fmt.Println("Running structure tests...")
-			if err := runStructureTests(); err != nil {
-				return fmt.Errorf("structure tests failed: %w", err)
-			}
+		var list []scanners.Scanner
+
+		if project.Cfg.Testing.StructureTests.Enabled {
+			list = append(list, scanners.NewStructureScanner(scanners.StructureTestsConfig(project.Cfg.Testing.StructureTests)))
+		}
+		if project.Cfg.Testing.IntegrationTests.Enabled {
+			list = append(list, scanners.NewIntegrationScanner(scanners.IntegrationTestsConfig(project.Cfg.Testing.IntegrationTests)))
+		}
+		if project.Cfg.Testing.PerformanceTests.Enabled {
+			list = append(list, scanners.NewPerformanceScanner(scanners.PerformanceTestsConfig(project.Cfg.Testing.PerformanceTests)))
 		}
 
-		// Integration tests
-		if config.Testing.IntegrationTests.Enabled {
-			fmt.Println("Running integration tests...")
-			if err := runIntegrationTests(); err != nil {
-				return fmt.Errorf("integration tests failed: %w", err)
-			}
+		timeout, err := scanners.ParseTimeout(project.Cfg.Testing.IntegrationTests.Timeout)
+		if err != nil {
+			return err
 		}
 
-		// Performance tests
-		if config.Testing.PerformanceTests.Enabled {
-			fmt.Println("Running performance tests...")
-			if err := runPerformanceTests(); err != nil {
-				return fmt.Errorf("performance tests failed: %w", err)
-			}
+		reports, err := scanners.RunAll(ctx, list, project.imageName(), testParallel, timeout)
+		if err != nil {
+			return fmt.Errorf("tests failed: %w", err)
+		}
+
+		path, err := scanners.WriteReport(".foundry/reports", "test.sarif.json", reports)
+		if err != nil {
+			return fmt.Errorf("failed to write test report: %w", err)
 		}
+		fmt.Printf("Wrote SARIF report to %s\n", path)
 
-		fmt.Println("‚úÖ All tests passed!")
+		fmt.Println("✅ All tests passed!")
 		return nil
 	},
 }
 
+var scanParallel int
+
 var scanCmd = &cobra.Command{
 	Use:   "scan",
 	Short: "Run security scans on images",
-	Long:  `Runs Trivy, CodeQL, SAST, and compliance scans on images.`,
+	Long: `Runs Trivy, SAST, and compliance scans on images as pluggable
+scanners.Scanner implementations, fanned out with --parallel workers.
+Results are aggregated into a SARIF 2.1.0 report under
+./.foundry/reports/scan.sarif.json, ready to upload to GitHub
+code-scanning.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("üîí Running security scans...")
+		fmt.Println("\U0001f512 Running security scans...")
 
-		if err := loadConfig(); err != nil {
+		ctx := cmd.Context()
+		project, err := LoadProject(ctx, configPath(cmd))
+		if err != nil {
 			return err
 		}
 
-		// Trivy scan
-		if config.Security.Trivy.Enabled {
-			fmt.Println("Running Trivy vulnerability scan...")
-			if err := runTrivyScan(); err != nil {
-				return fmt.Errorf("Trivy scan failed: %w", err)
-			}
+		var list []scanners.Scanner
+
+		if project.Cfg.Security.Trivy.Enabled {
+			list = append(list, scanners.NewTrivyScanner(scanners.TrivyConfig(project.Cfg.Security.Trivy)))
+		}
+		if project.Cfg.Security.Compliance.Enabled {
+			list = append(list, scanners.NewComplianceScanner(scanners.ComplianceConfig(project.Cfg.Security.Compliance)))
+		}
+		if project.Cfg.Security.SAST.Enabled {
+			list = append(list, scanners.NewSASTScanner(scanners.SASTConfig(project.Cfg.Security.SAST)))
 		}
 
-		// Compliance check
-		if config.Security.Compliance.Enabled {
-			fmt.Println("Running compliance checks...")
-			if err := runComplianceChecks(); err != nil {
-				return fmt.Errorf("compliance check failed: %w", err)
-			}
+		reports, err := scanners.RunAll(ctx, list, project.imageName(), scanParallel, 0)
+		if err != nil {
+			return fmt.Errorf("security scans failed: %w", err)
 		}
 
-		// SAST
-		if config.Security.SAST.Enabled {
-			fmt.Println("Running SAST analysis...")
-			if err := runSAST(); err != nil {
-				return fmt.Errorf("SAST failed: %w", err)
-			}
+		path, err := scanners.WriteReport(".foundry/reports", "scan.sarif.json", reports)
+		if err != nil {
+			return fmt.Errorf("failed to write scan report: %w", err)
 		}
+		fmt.Printf("Wrote SARIF report to %s\n", path)
 
-		fmt.Println("‚úÖ Security scans completed!")
+		fmt.Println("✅ Security scans completed!")
 		return nil
 	},
 }
 
+func init() {
+	testCmd.Flags().IntVar(&testParallel, "parallel", 4, "maximum number of scanners to run concurrently")
+	scanCmd.Flags().IntVar(&scanParallel, "parallel", 4, "maximum number of scanners to run concurrently")
+}
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("ImageFoundry v0.1.0")
+		fmt.Println("ImageFoundry v" + foundryVersion)
 		fmt.Println("A powerful container image builder with E2E CI/CD")
 	},
 }
@@ -387,7 +411,7 @@ var initCmd = &cobra.Command{
 	Short: "Initialize a new ImageFoundry project",
 	Long:  `Creates a new ImageFoundry project with example configuration and templates.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("üöÄ Initializing new ImageFoundry project...")
+		fmt.Println("üöÄ Initializing new ImageFoundry project...")
 
 		// Create directory structure
 		dirs := []string{
@@ -455,99 +479,8 @@ security:
 	},
 }
 
-func loadConfig() error {
-	if config.Name != "" {
-		return nil // Already loaded
-	}
-
-	if _, err := os.Stat(cfgFile); os.IsNotExist(err) {
-		return fmt.Errorf("config file not found: %s", cfgFile)
-	}
-
-	data, err := os.ReadFile(cfgFile)
-	if err != nil {
-		return fmt.Errorf("failed to read config: %w", err)
-	}
-
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return fmt.Errorf("failed to parse config: %w", err)
-	}
-
-	return nil
-}
-
-func buildImage(arch string) error {
-	imageName := fmt.Sprintf("%s/%s/%s:%s",
-		config.Image.Registry,
-		config.Image.Namespace,
-		config.Image.Name,
-		config.Image.Tag,
-	)
-
-	templatePath := filepath.Join("templates", "base", config.Base.Template+".Dockerfile")
-
-	// Build args
-	buildArgs := []string{
-		"buildx", "build",
-		"--platform", "linux/" + arch,
-		"--file", templatePath,
-		"--tag", imageName + "-" + arch,
-	}
-
-	// Add tool build args
-	for toolName, toolConfig := range config.Tools.Languages {
-		if toolConfig.Install {
-			buildArgs = append(buildArgs, "--build-arg", fmt.Sprintf("%s_VERSION=%s",
-				strings.ToUpper(toolName)+"_VERSION", toolConfig.Version))
-		}
-	}
-
-	buildArgs = append(buildArgs, "--push", ".")
-
-	fmt.Printf("  Running: docker %s\n", strings.Join(buildArgs, " "))
-
-	// In real implementation, this would execute docker buildx
-	return runCommand("docker " + strings.Join(buildArgs, " "))
-}
-
-func runCommand(cmd string) error {
-	// Simplified - would use exec.Command in real implementation
-	fmt.Printf("Executing: %s\n", cmd)
-	return nil
-}
-
-func runStructureTests() error {
-	// Placeholder for container-structure-test
-	return nil
-}
-
-func runIntegrationTests() error {
-	// Placeholder for integration tests
-	return nil
-}
-
-func runPerformanceTests() error {
-	// Placeholder for performance tests
-	return nil
-}
-
-func runTrivyScan() error {
-	// Placeholder for Trivy scan
-	return nil
-}
-
-func runComplianceChecks() error {
-	// Placeholder for compliance checks
-	return nil
-}
-
-func runSAST() error {
-	// Placeholder for SAST
-	return nil
-}
-
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	if err := rootCmd.ExecuteContext(context.Background()); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
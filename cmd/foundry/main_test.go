@@ -1,13 +1,13 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
 )
 
-func TestLoadConfig(t *testing.T) {
+func TestLoadProject(t *testing.T) {
 	// Create a temporary config file
 	tmpDir := t.TempDir()
 	configFile := filepath.Join(tmpDir, "test-config.yaml")
@@ -31,106 +31,104 @@ base:
 		t.Fatalf("Failed to create test config: %v", err)
 	}
 
-	// Test loading config
-	cfgFile = configFile
-	config = Config{} // Reset config
+	project, err := LoadProject(context.Background(), configFile)
+	if err != nil {
+		t.Fatalf("LoadProject() failed: %v", err)
+	}
 
-	if err := loadConfig(); err != nil {
-		t.Errorf("loadConfig() failed: %v", err)
+	if project.Cfg.Name != "test-project" {
+		t.Errorf("Expected name 'test-project', got '%s'", project.Cfg.Name)
 	}
 
-	if config.Name != "test-project" {
-		t.Errorf("Expected name 'test-project', got '%s'", config.Name)
+	if project.Cfg.Image.Name != "test-image" {
+		t.Errorf("Expected image name 'test-image', got '%s'", project.Cfg.Image.Name)
 	}
 
-	if config.Image.Name != "test-image" {
-		t.Errorf("Expected image name 'test-image', got '%s'", config.Image.Name)
+	if project.Root != tmpDir {
+		t.Errorf("Expected root %q, got %q", tmpDir, project.Root)
 	}
 }
 
+func TestLoadProjectMissingFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if _, err := LoadProject(context.Background(), filepath.Join(tmpDir, "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing config file, got nil")
+	}
+}
+
+// TestValidateConfig drives validateConfig with sparse, hand-written YAML
+// rather than yaml.Marshal of a zero-value Config: marshaling the full
+// struct always emits every key (present-but-empty), which JSON-Schema's
+// `required` treats as satisfied, so it can't exercise a genuinely missing
+// field. Omitting the key entirely is the only way to do that.
 func TestValidateConfig(t *testing.T) {
 	tests := []struct {
 		name    string
-		config  Config
+		yaml    string
 		wantErr bool
 	}{
 		{
 			name: "valid config",
-			config: Config{
-				Name: "test",
-				Base: struct {
-					Template     string   `yaml:"template" json:"template"`
-					Architecture []string `yaml:"architecture" json:"architecture"`
-				}{
-					Template:     "ubuntu-24.04",
-					Architecture: []string{"amd64"},
-				},
-			},
+			yaml: `
+name: test
+base:
+  template: ubuntu-24.04
+  architecture: [amd64]
+`,
 			wantErr: false,
 		},
 		{
 			name: "missing name",
-			config: Config{
-				Name: "",
-				Base: struct {
-					Template     string   `yaml:"template" json:"template"`
-					Architecture []string `yaml:"architecture" json:"architecture"`
-				}{
-					Template:     "ubuntu-24.04",
-					Architecture: []string{"amd64"},
-				},
-			},
+			yaml: `
+base:
+  template: ubuntu-24.04
+  architecture: [amd64]
+`,
 			wantErr: true,
 		},
 		{
 			name: "missing template",
-			config: Config{
-				Name: "test",
-				Base: struct {
-					Template     string   `yaml:"template" json:"template"`
-					Architecture []string `yaml:"architecture" json:"architecture"`
-				}{
-					Template:     "",
-					Architecture: []string{"amd64"},
-				},
-			},
+			yaml: `
+name: test
+base:
+  architecture: [amd64]
+`,
 			wantErr: true,
 		},
 		{
-			name: "no architecture",
-			config: Config{
-				Name: "test",
-				Base: struct {
-					Template     string   `yaml:"template" json:"template"`
-					Architecture []string `yaml:"architecture" json:"architecture"`
-				}{
-					Template:     "ubuntu-24.04",
-					Architecture: []string{},
-				},
-			},
+			name: "missing architecture",
+			yaml: `
+name: test
+base:
+  template: ubuntu-24.04
+`,
+			wantErr: true,
+		},
+		{
+			name: "unknown key",
+			yaml: `
+name: test
+typooo: whoops
+base:
+  template: ubuntu-24.04
+  architecture: [amd64]
+`,
 			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			config = tt.config
-
 			// Create a temporary directory with a template file for validation
 			tmpDir := t.TempDir()
 			templatesDir := filepath.Join(tmpDir, "templates", "base")
 			os.MkdirAll(templatesDir, 0755)
 
-			// Create a dummy template file
-			templateFile := filepath.Join(templatesDir, tt.config.Base.Template+".Dockerfile")
-			os.WriteFile(templateFile, []byte("FROM ubuntu\n"), 0644)
-
-			// Change to temp directory
-			oldWd, _ := os.Getwd()
-			os.Chdir(tmpDir)
-			defer os.Chdir(oldWd)
+			// Create a dummy template file so base.template has a valid enum value.
+			os.WriteFile(filepath.Join(templatesDir, "ubuntu-24.04.Dockerfile"), []byte("FROM ubuntu\n"), 0644)
 
-			err := validateConfig()
+			err := validateConfig([]byte(tt.yaml), tmpDir)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("validateConfig() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -138,18 +136,16 @@ func TestValidateConfig(t *testing.T) {
 	}
 }
 
-func TestBuildImageName(t *testing.T) {
-	config = Config{}
-	config.Image.Registry = "ghcr.io"
-	config.Image.Namespace = "myorg"
-	config.Image.Name = "myimage"
-	config.Image.Tag = "latest"
+func TestProjectImageName(t *testing.T) {
+	var p Project
+	p.Cfg.Image.Registry = "ghcr.io"
+	p.Cfg.Image.Namespace = "myorg"
+	p.Cfg.Image.Name = "myimage"
+	p.Cfg.Image.Tag = "latest"
 
-	expected := "ghcr.io/myorg/myimage:latest-amd64"
-	result := getImageName("amd64")
-
-	if result != expected {
-		t.Errorf("getImageName() = %v, want %v", result, expected)
+	expected := "ghcr.io/myorg/myimage:latest"
+	if result := p.imageName(); result != expected {
+		t.Errorf("imageName() = %v, want %v", result, expected)
 	}
 }
 
@@ -158,38 +154,12 @@ func TestArchitectureValidation(t *testing.T) {
 
 	for _, arch := range validArchs {
 		t.Run("arch_"+arch, func(t *testing.T) {
-			config = Config{}
-			config.Base.Architecture = []string{arch}
+			var cfg Config
+			cfg.Base.Architecture = []string{arch}
 
-			if len(config.Base.Architecture) != 1 {
-				t.Errorf("Expected 1 architecture, got %d", len(config.Base.Architecture))
+			if len(cfg.Base.Architecture) != 1 {
+				t.Errorf("Expected 1 architecture, got %d", len(cfg.Base.Architecture))
 			}
 		})
 	}
 }
-
-func getImageName(arch string) string {
-	return fmt.Sprintf("%s/%s/%s:%s-%s",
-		config.Image.Registry,
-		config.Image.Namespace,
-		config.Image.Name,
-		config.Image.Tag,
-		arch,
-	)
-}
-
-func validateConfig() error {
-	if config.Name == "" {
-		return fmt.Errorf("project name is required")
-	}
-
-	if config.Base.Template == "" {
-		return fmt.Errorf("base template is required")
-	}
-
-	if len(config.Base.Architecture) == 0 {
-		return fmt.Errorf("at least one architecture is required")
-	}
-
-	return nil
-}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/simonbbbb/imagefoundry/internal/templates"
+	"gopkg.in/yaml.v3"
+)
+
+// Project is the loaded, per-invocation state every command operates on.
+// It replaces the package-level config/cfgFile singletons loadConfig used
+// to mutate: each command builds its own Project from LoadProject instead
+// of relying on shared state that leaked between subcommands (and made the
+// package impossible to test in parallel).
+type Project struct {
+	Cfg    Config
+	Root   string
+	Logger *slog.Logger
+}
+
+// LoadProject reads and parses the image-foundry.yaml at path (defaulting
+// to "image-foundry.yaml" in the current directory when path is empty) and
+// returns a Project scoped to it. Root is the directory the config file
+// lives in, so path-relative lookups (templates, fragments, lockfiles)
+// resolve the same way regardless of the caller's working directory.
+func LoadProject(ctx context.Context, path string) (*Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if path == "" {
+		path = "image-foundry.yaml"
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, fmt.Errorf("config file not found: %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	return &Project{
+		Cfg:    cfg,
+		Root:   filepath.Dir(path),
+		Logger: slog.New(slog.NewTextHandler(os.Stderr, nil)),
+	}, nil
+}
+
+// imageName is the image reference scan/test/sign run against, before the
+// per-architecture suffix buildImage appends during a build.
+func (p *Project) imageName() string {
+	return fmt.Sprintf("%s/%s/%s:%s",
+		p.Cfg.Image.Registry,
+		p.Cfg.Image.Namespace,
+		p.Cfg.Image.Name,
+		p.Cfg.Image.Tag,
+	)
+}
+
+// templatePath is where a local base template is expected to live, relative
+// to the project root. It only makes sense for local template names; oci://
+// and git+ refs are fetched through resolveTemplate instead.
+func (p *Project) templatePath() string {
+	return filepath.Join(p.Root, "templates", "base", p.Cfg.Base.Template+".Dockerfile")
+}
+
+// resolveTemplate resolves Cfg.Base.Template to a concrete, on-disk
+// Dockerfile. Local names resolve straight to templatePath(); oci:// and
+// git+ refs are fetched (or served from cache) via the templates package.
+// Remote refs are pinned to the digest recorded in foundry.lock unless
+// update is true, in which case the ref is re-resolved from scratch and the
+// lock rewritten with whatever it now resolves to (foundry template update).
+func (p *Project) resolveTemplate(ctx context.Context, update bool) (templates.Resolved, error) {
+	ref := p.Cfg.Base.Template
+
+	if !templates.IsRemote(ref) {
+		path := p.templatePath()
+		digest, err := fileDigest(path)
+		if err != nil {
+			return templates.Resolved{}, fmt.Errorf("local template %q not found: %w", ref, err)
+		}
+		return templates.Resolved{Path: path, Digest: digest}, nil
+	}
+
+	resolver, err := templates.NewResolver(p.Root)
+	if err != nil {
+		return templates.Resolved{}, err
+	}
+
+	lock, err := readLock(p.Root)
+	if err != nil {
+		return templates.Resolved{}, err
+	}
+
+	pinned := ""
+	if !update {
+		pinned = lock.Templates[ref]
+	}
+
+	resolved, err := resolver.Resolve(ctx, ref, pinned)
+	if err != nil {
+		return templates.Resolved{}, err
+	}
+
+	lock.Templates[ref] = resolved.Digest
+	if err := writeLock(p.Root, lock); err != nil {
+		return templates.Resolved{}, err
+	}
+
+	return resolved, nil
+}
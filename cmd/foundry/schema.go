@@ -0,0 +1,269 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// knownEnums maps a dotted yaml-tag path (as produced by buildSchema) to the
+// set of values it's constrained to. Paths are relative to the Config root,
+// e.g. "base.architecture" for Config.Base.Architecture.
+var knownEnums = map[string][]string{
+	"base.architecture":             {"amd64", "arm64", "arm/v7", "386"},
+	"security.compliance.standards": {"PCI-DSS", "HIPAA", "SOC2", "CIS", "NIST"},
+	"output.sbom.formats":           {"spdx", "cyclonedx", "syft-json"},
+}
+
+// security.trivy.severity is deliberately not in knownEnums: it's a
+// comma-joined string like "HIGH,CRITICAL" (see initCmd's scaffold value),
+// not a single token, so a plain enum constraint would reject the default
+// config foundry init itself generates.
+
+// requiredPaths mirrors the fields validateConfig has always treated as
+// mandatory. Kept as an explicit list (rather than inferred from absence of
+// `omitempty`) since the Config struct doesn't use omitempty today.
+var requiredPaths = map[string][]string{
+	"":     {"name", "base"},
+	"base": {"template", "architecture"},
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Generate a JSON Schema for image-foundry.yaml",
+	Long: `Walks the Config struct via reflection and emits a Draft-07 JSON
+Schema to stdout, with enum constraints for known templates, architectures,
+SBOM formats, and compliance standards. Trivy severity is a comma-joined
+list and is not enum-constrained. Point your editor's
+"yaml.schemas" setting at the output (or pipe it to a file) to get
+autocomplete and inline validation for image-foundry.yaml.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root := filepath.Dir(configPathOrDefault(cmd))
+
+		schema, err := buildSchema(root)
+		if err != nil {
+			return fmt.Errorf("failed to generate schema: %w", err)
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(schema)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
+
+// configPathOrDefault is configPath with the same "image-foundry.yaml"
+// fallback LoadProject applies, for commands (like schema) that need a root
+// directory without loading the full config.
+func configPathOrDefault(cmd *cobra.Command) string {
+	path := configPath(cmd)
+	if path == "" {
+		path = "image-foundry.yaml"
+	}
+	return path
+}
+
+// buildSchema reflects over Config and produces a Draft-07 JSON Schema
+// document, annotated with the enum constraints in knownEnums and the
+// discovered set of base templates under root/templates/base/*.Dockerfile.
+func buildSchema(root string) (map[string]any, error) {
+	templates, err := discoverTemplates(root)
+	if err != nil {
+		return nil, err
+	}
+	// Draft-07 requires enum to have at least one value, so only constrain
+	// base.template once something under templates/base/ has been found;
+	// right after `foundry init` that directory is empty.
+	if len(templates) > 0 {
+		knownEnums["base.template"] = templates
+	} else {
+		delete(knownEnums, "base.template")
+	}
+
+	schema := schemaForType(reflect.TypeOf(Config{}), "")
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["$id"] = "https://github.com/yourorg/imagefoundry/image-foundry.schema.json"
+	schema["title"] = "ImageFoundry configuration"
+
+	return schema, nil
+}
+
+// discoverTemplates lists the base template names available under
+// root/templates/base/*.Dockerfile, e.g. "ubuntu-24.04" for
+// templates/base/ubuntu-24.04.Dockerfile.
+func discoverTemplates(root string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(root, "templates", "base", "*.Dockerfile"))
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		base := filepath.Base(m)
+		names = append(names, strings.TrimSuffix(base, ".Dockerfile"))
+	}
+
+	return names, nil
+}
+
+// schemaForType recursively builds a JSON Schema fragment for t. path is the
+// dotted yaml-tag path of t from the Config root, used to look up enum
+// constraints and required fields.
+func schemaForType(t reflect.Type, path string) map[string]any {
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := map[string]any{}
+		required := requiredPaths[path]
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := yamlFieldName(field)
+			if name == "-" {
+				continue
+			}
+
+			childPath := name
+			if path != "" {
+				childPath = path + "." + name
+			}
+
+			properties[name] = schemaForType(field.Type, childPath)
+		}
+
+		obj := map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+		if len(required) > 0 {
+			obj["required"] = required
+		}
+		return obj
+
+	case reflect.Slice, reflect.Array:
+		items := schemaForType(t.Elem(), path)
+		obj := map[string]any{
+			"type":  "array",
+			"items": items,
+		}
+		if enum, ok := knownEnums[path]; ok {
+			items["enum"] = enum
+		}
+		return obj
+
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": schemaForType(t.Elem(), path),
+		}
+
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+
+	default: // string and friends
+		obj := map[string]any{"type": "string"}
+		if enum, ok := knownEnums[path]; ok {
+			obj["enum"] = enum
+		}
+		return obj
+	}
+}
+
+func yamlFieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("yaml")
+	if tag == "" {
+		return strings.ToLower(field.Name)
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// validateConfig parses raw YAML config bytes into a generic document,
+// converts it to JSON, and validates it against the schema generated for the
+// templates under root with santhosh-tekuri/jsonschema, so typos, unknown
+// keys, and bad enum values are reported with JSON-pointer paths instead of
+// only the handful of fields the old hand-coded checks covered.
+func validateConfig(data []byte, root string) error {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	doc = normalizeYAMLMaps(doc)
+
+	docJSON, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to convert config to JSON: %w", err)
+	}
+	var jsonDoc any
+	if err := json.Unmarshal(docJSON, &jsonDoc); err != nil {
+		return fmt.Errorf("failed to convert config to JSON: %w", err)
+	}
+
+	schemaDoc, err := buildSchema(root)
+	if err != nil {
+		return fmt.Errorf("failed to build schema: %w", err)
+	}
+
+	schemaJSON, err := json.Marshal(schemaDoc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("image-foundry.schema.json", strings.NewReader(string(schemaJSON))); err != nil {
+		return fmt.Errorf("failed to load schema: %w", err)
+	}
+	compiled, err := compiler.Compile("image-foundry.schema.json")
+	if err != nil {
+		return fmt.Errorf("failed to compile schema: %w", err)
+	}
+
+	if err := compiled.Validate(jsonDoc); err != nil {
+		return fmt.Errorf("config does not match schema: %w", err)
+	}
+
+	return nil
+}
+
+// normalizeYAMLMaps converts the map[interface{}]interface{} nodes yaml.v3
+// produces for mappings with non-string keys into map[string]any
+// recursively, since encoding/json (and jsonschema) expect string-keyed maps
+// all the way down.
+func normalizeYAMLMaps(v any) any {
+	switch val := v.(type) {
+	case map[any]any:
+		out := make(map[string]any, len(val))
+		for k, child := range val {
+			out[fmt.Sprint(k)] = normalizeYAMLMaps(child)
+		}
+		return out
+	case map[string]any:
+		for k, child := range val {
+			val[k] = normalizeYAMLMaps(child)
+		}
+		return val
+	case []any:
+		for i, child := range val {
+			val[i] = normalizeYAMLMaps(child)
+		}
+		return val
+	default:
+		return val
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// foundryVersion is substituted into in-toto provenance's builder.id as
+// "foundry@<version>"; it also backs `foundry version`.
+const foundryVersion = "0.1.0"
+
+var signCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "Sign a built image and attach SLSA/SBOM attestations",
+	Long: `Signs the image produced by "foundry build" with cosign and, when
+configured, attaches an in-toto SLSA v1.0 provenance attestation and the
+generated SBOM as predicates. Keyless signing (output.signing.cosign.keyless)
+goes through Fulcio/OIDC, reading SIGSTORE_ID_TOKEN or the GitHub Actions
+OIDC token the same way "cosign sign" does natively.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		project, err := LoadProject(ctx, configPath(cmd))
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		digest, err := resolveDigest(ctx, project.imageName())
+		if err != nil {
+			return fmt.Errorf("failed to resolve image digest: %w", err)
+		}
+
+		return signAndAttest(ctx, project, digest)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(signCmd)
+}
+
+// signAndAttest runs the post-build signing pipeline against the manifest
+// list at digest: cosign signing, SLSA provenance attestation, and SBOM
+// attestation, each gated by its own config flag. digest is the bare
+// sha256:... digest returned by buildImages/assembleManifest.
+func signAndAttest(ctx context.Context, p *Project, digest string) error {
+	if digest == "" {
+		return nil
+	}
+
+	ref := p.imageName() + "@" + digest
+
+	if p.Cfg.Output.Signing.Cosign.Enabled {
+		if err := cosignSign(ctx, p, ref); err != nil {
+			return fmt.Errorf("cosign sign failed: %w", err)
+		}
+	}
+
+	if p.Cfg.Output.Attestation.Provenance {
+		if err := attestProvenance(ctx, p, ref); err != nil {
+			return fmt.Errorf("provenance attestation failed: %w", err)
+		}
+	}
+
+	if p.Cfg.Output.Attestation.SBOMAttestation {
+		if err := attestSBOM(ctx, p, ref); err != nil {
+			return fmt.Errorf("SBOM attestation failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// cosignSign signs ref. When Cosign.Keyless is set it relies on cosign's
+// own Fulcio/OIDC detection (SIGSTORE_ID_TOKEN, or the GitHub Actions
+// ACTIONS_ID_TOKEN_REQUEST_* env vars in CI) rather than a local key.
+func cosignSign(ctx context.Context, p *Project, ref string) error {
+	args := []string{"sign", "--yes"}
+
+	if p.Cfg.Output.Signing.Cosign.Keyless {
+		if os.Getenv("SIGSTORE_ID_TOKEN") == "" && os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN") == "" {
+			p.Logger.Warn("keyless signing requested but no OIDC token found in SIGSTORE_ID_TOKEN or ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+		}
+	}
+
+	args = append(args, ref)
+	return runCosign(ctx, args)
+}
+
+// provenancePredicate is a (deliberately trimmed) SLSA v1.0 provenance
+// predicate (https://slsa.dev/spec/v1.0/provenance): enough fields for
+// `cosign attest --predicate` to produce a useful in-toto statement without
+// pulling in the full slsa-framework go-types module. v1.0 replaced v0.2's
+// flat builder/buildType/invocation/materials shape with nested
+// buildDefinition/runDetails objects; resolvedDependencies is v1.0's name
+// for v0.2's materials.
+type provenancePredicate struct {
+	BuildDefinition struct {
+		BuildType            string               `json:"buildType"`
+		ExternalParameters   map[string]string    `json:"externalParameters"`
+		ResolvedDependencies []resourceDescriptor `json:"resolvedDependencies,omitempty"`
+	} `json:"buildDefinition"`
+	RunDetails struct {
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+	} `json:"runDetails"`
+}
+
+type resourceDescriptor struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+// attestProvenance builds an in-toto SLSA v1.0 provenance predicate for the
+// current build and attaches it to ref with `cosign attest`.
+func attestProvenance(ctx context.Context, p *Project, ref string) error {
+	predicate := buildProvenancePredicate(ctx, p)
+
+	predicatePath, err := writeJSONTemp("foundry-provenance-*.json", predicate)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(predicatePath)
+
+	return runCosign(ctx, []string{"attest", "--yes", "--type", "slsaprovenance1", "--predicate", predicatePath, ref})
+}
+
+// buildProvenancePredicate assembles the predicate attestProvenance attaches:
+// runDetails.builder.id = foundry@<version>, buildDefinition.
+// externalParameters.invocation = the cobra command line, and
+// resolvedDependencies = the git remote URL (pinned to HEAD's digest) plus
+// the resolved Dockerfile's digest. Either dependency is best-effort: a
+// build run outside a git checkout (or without a configured remote), or
+// against a template that fails to resolve, still gets a (shorter)
+// predicate rather than no attestation at all.
+func buildProvenancePredicate(ctx context.Context, p *Project) provenancePredicate {
+	var predicate provenancePredicate
+	predicate.BuildDefinition.BuildType = "https://github.com/yourorg/imagefoundry/buildtypes/foundry-build@v1"
+	predicate.BuildDefinition.ExternalParameters = map[string]string{
+		"invocation": strings.Join(os.Args, " "),
+	}
+	predicate.RunDetails.Builder.ID = "foundry@" + foundryVersion
+
+	head, headErr := gitHead(ctx)
+	remote, remoteErr := gitRemoteURL(ctx)
+	if headErr == nil && remoteErr == nil {
+		predicate.BuildDefinition.ResolvedDependencies = append(predicate.BuildDefinition.ResolvedDependencies, resourceDescriptor{
+			URI:    "git+" + remote,
+			Digest: map[string]string{"sha1": head},
+		})
+	}
+
+	if resolved, err := p.resolveTemplate(ctx, false); err == nil {
+		predicate.BuildDefinition.ResolvedDependencies = append(predicate.BuildDefinition.ResolvedDependencies, resourceDescriptor{
+			URI:    p.Cfg.Base.Template,
+			Digest: map[string]string{"sha256": resolved.Digest},
+		})
+	}
+
+	return predicate
+}
+
+// attestSBOM attaches the SBOM BuildKit generated during the build (via
+// buildImage's --sbom=true flag) as a cosign predicate, passing the first
+// format listed under output.sbom.formats (default spdx) as the predicate
+// --type. The SBOM itself is attached as BuildKit recorded it; it is not
+// converted to that format. buildx never writes
+// the SBOM to a file on disk -- it's recorded as an attestation on the
+// pushed manifest -- so it's read back the same way resolveDigest reads the
+// manifest digest, via `docker buildx imagetools inspect`.
+func attestSBOM(ctx context.Context, p *Project, ref string) error {
+	format := "spdx"
+	if len(p.Cfg.Output.SBOM.Formats) > 0 {
+		format = p.Cfg.Output.SBOM.Formats[0]
+	}
+
+	sbom, err := exec.CommandContext(ctx, "docker", "buildx", "imagetools", "inspect", ref, "--format", "{{json .SBOM}}").Output()
+	if err != nil {
+		return fmt.Errorf("failed to read SBOM attestation for %s: %w", ref, err)
+	}
+
+	predicatePath, err := writeTemp("foundry-sbom-*.json", sbom)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(predicatePath)
+
+	return runCosign(ctx, []string{"attest", "--yes", "--type", format, "--predicate", predicatePath, ref})
+}
+
+// resolveDigest looks up the content digest a (possibly floating) tag
+// currently resolves to, so `foundry sign` can be run standalone after a
+// build without the caller having to know the digest.
+func resolveDigest(ctx context.Context, ref string) (string, error) {
+	out, err := exec.CommandContext(ctx, "docker", "buildx", "imagetools", "inspect", ref, "--format", "{{json .Manifest.Digest}}").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect %s: %w", ref, err)
+	}
+
+	var digest string
+	if err := json.Unmarshal(out, &digest); err != nil {
+		return "", fmt.Errorf("failed to parse digest for %s: %w", ref, err)
+	}
+
+	return digest, nil
+}
+
+func runCosign(ctx context.Context, args []string) error {
+	fmt.Printf("  Running: cosign %s\n", strings.Join(args, " "))
+
+	cmd := exec.CommandContext(ctx, "cosign", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func gitHead(ctx context.Context) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func gitRemoteURL(ctx context.Context) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", "config", "--get", "remote.origin.url").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func fileDigest(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func writeJSONTemp(pattern string, v any) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+func writeTemp(pattern string, data []byte) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return "", err
+	}
+
+	return f.Name(), nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestAttestProvenanceBuildsPredicate(t *testing.T) {
+	tmpDir := t.TempDir()
+	templatesDir := filepath.Join(tmpDir, "templates", "base")
+	if err := os.MkdirAll(templatesDir, 0755); err != nil {
+		t.Fatalf("failed to create templates dir: %v", err)
+	}
+	dockerfilePath := filepath.Join(templatesDir, "ubuntu-24.04.Dockerfile")
+	if err := os.WriteFile(dockerfilePath, []byte("FROM ubuntu\n"), 0644); err != nil {
+		t.Fatalf("failed to write Dockerfile: %v", err)
+	}
+
+	configFile := filepath.Join(tmpDir, "image-foundry.yaml")
+	configContent := `
+name: test-project
+base:
+  template: "ubuntu-24.04"
+  architecture:
+    - amd64
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	project, err := LoadProject(context.Background(), configFile)
+	if err != nil {
+		t.Fatalf("LoadProject() failed: %v", err)
+	}
+
+	wantDigest, err := fileDigest(dockerfilePath)
+	if err != nil {
+		t.Fatalf("fileDigest() failed: %v", err)
+	}
+
+	predicate := buildProvenancePredicate(context.Background(), project)
+
+	if want := "foundry@" + foundryVersion; predicate.RunDetails.Builder.ID != want {
+		t.Errorf("RunDetails.Builder.ID = %q, want %q", predicate.RunDetails.Builder.ID, want)
+	}
+	if predicate.BuildDefinition.ExternalParameters["invocation"] == "" {
+		t.Error("ExternalParameters[\"invocation\"] is empty, want the command line")
+	}
+
+	var gotTemplateDigest string
+	for _, dep := range predicate.BuildDefinition.ResolvedDependencies {
+		if dep.URI == "ubuntu-24.04" {
+			gotTemplateDigest = dep.Digest["sha256"]
+		}
+	}
+	if gotTemplateDigest != wantDigest {
+		t.Errorf("resolved dependency digest for ubuntu-24.04 = %q, want %q", gotTemplateDigest, wantDigest)
+	}
+}
+
+// TestCosignVerifyAttestationRoundTrip exercises the attest ->
+// verify-attestation round trip against a real registry; it does not sign
+// the image first. It requires a
+// working cosign binary and network access, neither of which are available
+// in this sandbox, so it's skipped unless explicitly opted into.
+func TestCosignVerifyAttestationRoundTrip(t *testing.T) {
+	if os.Getenv("FOUNDRY_INTEGRATION_TEST") == "" {
+		t.Skip("set FOUNDRY_INTEGRATION_TEST=1 to run against a real registry with cosign installed")
+	}
+
+	if _, err := exec.LookPath("cosign"); err != nil {
+		t.Skip("cosign not found in PATH")
+	}
+
+	ctx := context.Background()
+	ref := os.Getenv("FOUNDRY_TEST_IMAGE_REF")
+	if ref == "" {
+		t.Fatal("FOUNDRY_TEST_IMAGE_REF must point at an image this test is allowed to sign and attest")
+	}
+
+	project, err := LoadProject(ctx, "")
+	if err != nil {
+		t.Fatalf("LoadProject() failed: %v", err)
+	}
+
+	if err := attestProvenance(ctx, project, ref); err != nil {
+		t.Fatalf("attestProvenance() failed: %v", err)
+	}
+
+	out, err := exec.CommandContext(ctx, "cosign", "verify-attestation", "--type", "slsaprovenance1", ref).CombinedOutput()
+	if err != nil {
+		t.Fatalf("cosign verify-attestation failed: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Manage the base template foundry.lock pins",
+}
+
+var templateUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Re-resolve base.template and rewrite foundry.lock",
+	Long: `Re-resolves base.template against its source (a no-op for local
+templates) and overwrites its entry in foundry.lock with whatever digest it
+resolves to now. Builds and validation otherwise stay pinned to the
+previously recorded digest for oci:// and git+ template refs, so they never
+silently pick up upstream changes.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		project, err := LoadProject(ctx, configPath(cmd))
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		resolved, err := project.resolveTemplate(ctx, true)
+		if err != nil {
+			return fmt.Errorf("failed to update template: %w", err)
+		}
+
+		fmt.Printf("%s -> %s (%s)\n", project.Cfg.Base.Template, resolved.Path, resolved.Digest)
+		return nil
+	},
+}
+
+func init() {
+	templateCmd.AddCommand(templateUpdateCmd)
+	rootCmd.AddCommand(templateCmd)
+}
@@ -0,0 +1,28 @@
+package scanners
+
+import "context"
+
+// ComplianceConfig mirrors the `security.compliance` section of
+// image-foundry.yaml.
+type ComplianceConfig struct {
+	Enabled   bool
+	Standards []string
+}
+
+type complianceScanner struct {
+	cfg ComplianceConfig
+}
+
+// NewComplianceScanner builds the "compliance" Scanner. Its Run is a
+// placeholder: it checks no standards and returns a Report with no findings.
+func NewComplianceScanner(cfg ComplianceConfig) Scanner {
+	return complianceScanner{cfg: cfg}
+}
+
+func (complianceScanner) Name() string { return "compliance" }
+
+func (c complianceScanner) Run(ctx context.Context, img string) (Report, error) {
+	// Placeholder: would run the configured standards' benchmarks against
+	// img and translate their output into Findings.
+	return Report{Scanner: c.Name()}, nil
+}
@@ -0,0 +1,30 @@
+package scanners
+
+import "context"
+
+// IntegrationTestsConfig mirrors the `testing.integration_tests` section
+// of image-foundry.yaml. Timeout is a Go duration string (e.g. "5m"),
+// parsed with ParseTimeout and applied per-run by RunAll.
+type IntegrationTestsConfig struct {
+	Enabled bool
+	Timeout string
+}
+
+type integrationScanner struct {
+	cfg IntegrationTestsConfig
+}
+
+// NewIntegrationScanner builds the "integration-tests" Scanner. Its Run is a
+// placeholder: it does not start the image or run any suite and returns a
+// Report with no findings.
+func NewIntegrationScanner(cfg IntegrationTestsConfig) Scanner {
+	return integrationScanner{cfg: cfg}
+}
+
+func (integrationScanner) Name() string { return "integration-tests" }
+
+func (s integrationScanner) Run(ctx context.Context, img string) (Report, error) {
+	// Placeholder: would start img as a container and run the integration
+	// suite against it, respecting ctx's deadline.
+	return Report{Scanner: s.Name()}, nil
+}
@@ -0,0 +1,29 @@
+package scanners
+
+import "context"
+
+// PerformanceTestsConfig mirrors the `testing.performance_tests` section
+// of image-foundry.yaml.
+type PerformanceTestsConfig struct {
+	Enabled       bool
+	BenchmarkTool string
+}
+
+type performanceScanner struct {
+	cfg PerformanceTestsConfig
+}
+
+// NewPerformanceScanner builds the "performance-tests" Scanner. Its Run is a
+// placeholder: it does not invoke the benchmark tool and returns a Report
+// with no findings.
+func NewPerformanceScanner(cfg PerformanceTestsConfig) Scanner {
+	return performanceScanner{cfg: cfg}
+}
+
+func (performanceScanner) Name() string { return "performance-tests" }
+
+func (p performanceScanner) Run(ctx context.Context, img string) (Report, error) {
+	// Placeholder: would invoke cfg.BenchmarkTool against img and translate
+	// its results into Findings.
+	return Report{Scanner: p.Name()}, nil
+}
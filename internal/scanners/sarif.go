@@ -0,0 +1,101 @@
+package scanners
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// sarifLog is a minimal SARIF 2.1.0 document: enough structure to upload
+// results to GitHub code-scanning without pulling in a full SARIF schema
+// implementation.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name string `json:"name"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// Aggregate merges one SARIF run per Report into a single SARIF log.
+func Aggregate(reports []Report) []byte {
+	log := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    make([]sarifRun, 0, len(reports)),
+	}
+
+	for _, r := range reports {
+		run := sarifRun{
+			Tool:    sarifTool{Driver: sarifDriver{Name: r.Scanner}},
+			Results: make([]sarifResult, 0, len(r.Findings)),
+		}
+
+		for _, f := range r.Findings {
+			run.Results = append(run.Results, sarifResult{
+				RuleID:  f.RuleID,
+				Level:   string(f.Severity),
+				Message: sarifMessage{Text: f.Message},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: f.Location},
+					},
+				}},
+			})
+		}
+
+		log.Runs = append(log.Runs, run)
+	}
+
+	data, _ := json.MarshalIndent(log, "", "  ")
+	return data
+}
+
+// WriteReport aggregates reports into a SARIF document and writes it to
+// dir/name, creating dir if needed, and returns the written path.
+func WriteReport(dir, name string, reports []Report) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, Aggregate(reports), 0644); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
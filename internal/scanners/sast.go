@@ -0,0 +1,27 @@
+package scanners
+
+import "context"
+
+// SASTConfig mirrors the `security.sast` section of image-foundry.yaml.
+type SASTConfig struct {
+	Enabled bool
+	Tools   []string
+}
+
+type sastScanner struct {
+	cfg SASTConfig
+}
+
+// NewSASTScanner builds the "sast" Scanner. Its Run is a placeholder: it
+// runs none of the configured tools and returns a Report with no findings.
+func NewSASTScanner(cfg SASTConfig) Scanner {
+	return sastScanner{cfg: cfg}
+}
+
+func (sastScanner) Name() string { return "sast" }
+
+func (s sastScanner) Run(ctx context.Context, img string) (Report, error) {
+	// Placeholder: would invoke each tool in cfg.Tools and translate its
+	// findings into Findings.
+	return Report{Scanner: s.Name()}, nil
+}
@@ -0,0 +1,111 @@
+// Package scanners defines the pluggable Scanner interface used by
+// `foundry scan` and `foundry test` to run Trivy, compliance, SAST,
+// structure, integration, and performance checks against a built image,
+// and to fan them out concurrently with per-scanner timeouts.
+package scanners
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Severity mirrors the SARIF "level" values a Finding can be reported at.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+)
+
+// Finding is a single issue surfaced by a Scanner, shaped closely enough to
+// SARIF's result object that Aggregate can translate it directly.
+type Finding struct {
+	RuleID   string
+	Severity Severity
+	Message  string
+	Location string
+}
+
+// Report is everything one Scanner produced for a single image.
+type Report struct {
+	Scanner  string
+	Findings []Finding
+}
+
+// Scanner is implemented by each check foundry can run against a built
+// image. Run must respect ctx cancellation/deadlines.
+type Scanner interface {
+	Name() string
+	Run(ctx context.Context, img string) (Report, error)
+}
+
+// RunAll fans Run out across scanners with at most `parallel` running at
+// once (parallel <= 0 means unbounded). If timeout > 0, each scanner gets
+// its own context.WithTimeout derived from ctx. Errors are collected per
+// scanner rather than aborting the others; the first one (if any) is
+// returned alongside whatever reports did complete.
+func RunAll(ctx context.Context, list []Scanner, img string, parallel int, timeout time.Duration) ([]Report, error) {
+	if parallel <= 0 || parallel > len(list) {
+		parallel = len(list)
+	}
+	if parallel == 0 {
+		return nil, nil
+	}
+
+	reports := make([]Report, len(list))
+	errs := make([]error, len(list))
+
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for i, s := range list {
+		i, s := i, s
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			runCtx := ctx
+			cancel := func() {}
+			if timeout > 0 {
+				runCtx, cancel = context.WithTimeout(ctx, timeout)
+			}
+			defer cancel()
+
+			report, err := s.Run(runCtx, img)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", s.Name(), err)
+				return
+			}
+			reports[i] = report
+		}()
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return reports, err
+		}
+	}
+
+	return reports, nil
+}
+
+// ParseTimeout parses a Go duration string such as IntegrationTestsConfig's
+// "Timeout" field (e.g. "5m", "90s"). An empty string means "no timeout".
+func ParseTimeout(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: %w", s, err)
+	}
+	return d, nil
+}
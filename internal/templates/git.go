@@ -0,0 +1,92 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// resolveGit fetches a single file out of a git ref, given a ref of the
+// form "https://host/org/repo//path/to/file.Dockerfile@ref" (the `//`
+// separates the repo URL from the in-repo path, Terraform-module style).
+// The clone is shallow (depth 1) and discarded once the file is read; only
+// the file itself is cached (under its content digest), not the full repo.
+func (r *Resolver) resolveGit(ctx context.Context, ref string, pinned string) (Resolved, error) {
+	repoURL, subPath, gitRef, err := parseGitRef(ref)
+	if err != nil {
+		return Resolved{}, err
+	}
+
+	filename := filepath.Base(subPath)
+
+	if pinned != "" {
+		if cachedFile, ok := r.cachedPath(pinned, filename); ok {
+			return Resolved{Path: cachedFile, Digest: pinned}, nil
+		}
+	}
+
+	cloneDir, err := os.MkdirTemp("", "foundry-template-*")
+	if err != nil {
+		return Resolved{}, err
+	}
+	defer os.RemoveAll(cloneDir)
+
+	_, err = git.PlainCloneContext(ctx, cloneDir, false, &git.CloneOptions{
+		URL:           repoURL,
+		ReferenceName: plumbing.NewTagReferenceName(gitRef),
+		Depth:         1,
+		SingleBranch:  true,
+	})
+	if err != nil {
+		// Fall back to treating gitRef as a branch rather than a tag.
+		_, err = git.PlainCloneContext(ctx, cloneDir, false, &git.CloneOptions{
+			URL:           repoURL,
+			ReferenceName: plumbing.NewBranchReferenceName(gitRef),
+			Depth:         1,
+			SingleBranch:  true,
+		})
+		if err != nil {
+			return Resolved{}, fmt.Errorf("failed to clone %s@%s: %w", repoURL, gitRef, err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(cloneDir, subPath))
+	if err != nil {
+		return Resolved{}, fmt.Errorf("%s not found in %s@%s: %w", subPath, repoURL, gitRef, err)
+	}
+
+	digest := sha256Hex(data)
+	if pinned != "" && digest != pinned {
+		return Resolved{}, fmt.Errorf("template %s resolved to %s, want pinned digest %s (run 'foundry template update' to accept the change)", ref, digest, pinned)
+	}
+
+	cachedFile, err := r.writeCache(digest, filename, data)
+	if err != nil {
+		return Resolved{}, err
+	}
+
+	return Resolved{Path: cachedFile, Digest: digest}, nil
+}
+
+// parseGitRef splits "host/org/repo//path/to/file@ref" into its repo URL,
+// in-repo path, and git ref. The separator is the LAST "//" in the ref, not
+// the first, since schemes like "https://" also contain one.
+func parseGitRef(ref string) (repoURL, subPath, gitRef string, err error) {
+	idx := strings.LastIndex(ref, "//")
+	if idx == -1 {
+		return "", "", "", fmt.Errorf("git template ref %q must contain '//' separating the repo URL from the in-repo path", ref)
+	}
+	repoURL = ref[:idx]
+
+	pathAndRef := strings.SplitN(ref[idx+2:], "@", 2)
+	if len(pathAndRef) != 2 {
+		return "", "", "", fmt.Errorf("git template ref %q must end in '@<ref>'", ref)
+	}
+
+	return repoURL, pathAndRef[0], pathAndRef[1], nil
+}
@@ -0,0 +1,82 @@
+package templates
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// resolveOCI fetches the Dockerfile packaged in the OCI artifact at ref
+// (e.g. "ghcr.io/org/foundry-templates/ubuntu:24.04"), expecting it to be
+// the single file in the artifact's last layer (the ORAS single-file
+// artifact convention). When pinned matches content already cached, the
+// registry isn't contacted at all.
+func (r *Resolver) resolveOCI(ctx context.Context, ref string, pinned string) (Resolved, error) {
+	filename := "Dockerfile"
+
+	if pinned != "" {
+		if cachedFile, ok := r.cachedPath(pinned, filename); ok {
+			return Resolved{Path: cachedFile, Digest: pinned}, nil
+		}
+	}
+
+	img, err := crane.Pull(ref, crane.WithContext(ctx))
+	if err != nil {
+		return Resolved{}, fmt.Errorf("failed to pull OCI template %s: %w", ref, err)
+	}
+
+	data, err := readSingleFileLayer(img)
+	if err != nil {
+		return Resolved{}, fmt.Errorf("failed to read Dockerfile from %s: %w", ref, err)
+	}
+
+	digest := sha256Hex(data)
+	if pinned != "" && digest != pinned {
+		return Resolved{}, fmt.Errorf("template %s resolved to %s, want pinned digest %s (run 'foundry template update' to accept the change)", ref, digest, pinned)
+	}
+
+	cachedFile, err := r.writeCache(digest, filename, data)
+	if err != nil {
+		return Resolved{}, err
+	}
+
+	return Resolved{Path: cachedFile, Digest: digest}, nil
+}
+
+// readSingleFileLayer extracts the Dockerfile out of img's last layer, which
+// is read as a tarball: the first entry named "Dockerfile" or ending in
+// ".Dockerfile" is returned.
+func readSingleFileLayer(img v1.Image) ([]byte, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("artifact has no layers")
+	}
+
+	rc, err := layers[len(layers)-1].Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("no Dockerfile found in artifact layer")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if path.Base(hdr.Name) == "Dockerfile" || path.Ext(hdr.Name) == ".Dockerfile" {
+			return io.ReadAll(tr)
+		}
+	}
+}
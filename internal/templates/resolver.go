@@ -0,0 +1,120 @@
+// Package templates resolves the base template a config.Base.Template
+// value points at, whether that's a local file under templates/base/, an
+// OCI artifact, or a file inside a git repository.
+package templates
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Resolved is a template Dockerfile that's been located on local disk
+// (fetched, or already cached), pinned to the sha256 digest of its
+// contents.
+type Resolved struct {
+	Path   string
+	Digest string
+}
+
+// Resolver locates the Dockerfile a config.Base.Template value refers to.
+// Three forms are supported:
+//
+//   - "oci://registry/repo:tag"                                 an OCI artifact
+//   - "git+https://host/org/repo//path/to/file.Dockerfile@ref"  a file in a git ref
+//   - "name"                                                    templates/base/name.Dockerfile on disk
+//
+// OCI and git fetches are cached under CacheDir, keyed by content digest,
+// so repeat builds against an unchanged ref don't hit the network again.
+type Resolver struct {
+	// CacheDir is the root remote artifacts are cached under.
+	CacheDir string
+	// Root is the project root local template names are resolved
+	// relative to (Root/templates/base/<name>.Dockerfile).
+	Root string
+}
+
+// NewResolver builds a Resolver rooted at root, caching remote fetches
+// under the user's cache directory (~/.cache/foundry/templates on Linux).
+func NewResolver(root string) (*Resolver, error) {
+	userCache, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine cache directory: %w", err)
+	}
+
+	return &Resolver{
+		CacheDir: filepath.Join(userCache, "foundry", "templates"),
+		Root:     root,
+	}, nil
+}
+
+// Resolve locates the Dockerfile ref points at. When pinned is non-empty
+// (the digest previously recorded in foundry.lock), a remote ref already
+// cached under that digest is served from disk with no network access;
+// pass "" to force re-resolution (foundry template update).
+func (r *Resolver) Resolve(ctx context.Context, ref string, pinned string) (Resolved, error) {
+	switch {
+	case strings.HasPrefix(ref, "oci://"):
+		return r.resolveOCI(ctx, strings.TrimPrefix(ref, "oci://"), pinned)
+	case strings.HasPrefix(ref, "git+"):
+		return r.resolveGit(ctx, strings.TrimPrefix(ref, "git+"), pinned)
+	default:
+		return r.resolveLocal(ref)
+	}
+}
+
+// IsRemote reports whether ref is an oci:// or git+ URI rather than a
+// bare local template name.
+func IsRemote(ref string) bool {
+	return strings.HasPrefix(ref, "oci://") || strings.HasPrefix(ref, "git+")
+}
+
+func (r *Resolver) resolveLocal(name string) (Resolved, error) {
+	path := filepath.Join(r.Root, "templates", "base", name+".Dockerfile")
+
+	digest, err := fileDigest(path)
+	if err != nil {
+		return Resolved{}, fmt.Errorf("local template %q not found: %w", name, err)
+	}
+
+	return Resolved{Path: path, Digest: digest}, nil
+}
+
+// cachedPath returns where a remote artifact pinned to digest would live,
+// and whether it's already present.
+func (r *Resolver) cachedPath(digest, filename string) (path string, cached bool) {
+	path = filepath.Join(r.CacheDir, digest, filename)
+	_, err := os.Stat(path)
+	return path, err == nil
+}
+
+func (r *Resolver) writeCache(digest, filename string, data []byte) (string, error) {
+	dir := filepath.Join(r.CacheDir, digest)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create template cache dir: %w", err)
+	}
+
+	path := filepath.Join(dir, filename)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write cached template: %w", err)
+	}
+
+	return path, nil
+}
+
+func fileDigest(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return sha256Hex(data), nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}